@@ -23,6 +23,12 @@ var (
 // to be removed from a folder in order to free up a given amount of space.
 type RemovingStrategy func(folder *BroomFolder, files *list.List, needReduce Size) []File
 
+// GlobalRemovingStrategy defines the signature of functions that select
+// files to remove across every managed folder in order to free up
+// needReduce bytes once their combined CurrentSize exceeds GlobalMaxSize.
+// See DEFAULT_GLOBAL_REMOVING_STRATEGY and FAIRNESS_WEIGHTED_GLOBAL_REMOVING_STRATEGY.
+type GlobalRemovingStrategy func(folders map[string]*BroomFolder, needReduce Size) map[*BroomFolder][]File
+
 // call when a file has beem removed
 type OnRemoveCallback func(folder *BroomFolder, fileToRemove []File)
 
@@ -40,18 +46,67 @@ type Broom struct {
 
 	RemovingStrategy RemovingStrategy
 	onRemoveCb       OnRemoveCallback
+	metaDataReader   MetadateReader
 	sweepTime        time.Duration
+	Versioner        Versioner
+	Filesystem       Filesystem
+
+	// GlobalMaxSize, when non-zero, caps the combined CurrentSize of every
+	// managed folder. loop runs GlobalRemovingStrategy once that total is
+	// exceeded, in addition to each folder's own per-folder MaxSize.
+	//
+	// Only initialized folders count toward the total and are eligible as
+	// victims (see BroomFolder.isInitialized); a folder added via
+	// AddFolder/AddFolderCtx only becomes initialized once GetFolder,
+	// RecheckFolder, or an fsnotify event touches it, so a freshly added,
+	// otherwise-untouched folder is invisible to the global quota.
+	GlobalMaxSize          Size
+	GlobalRemovingStrategy GlobalRemovingStrategy
 	// state
 }
 
+// BroomOption configures optional Broom behavior at construction time.
+type BroomOption func(*Broom)
+
+// WithVersioner makes Broom hand victim files to v instead of deleting them
+// outright; see the Versioner interface for the archive-vs-delete contract.
+func WithVersioner(v Versioner) BroomOption {
+	return func(br *Broom) {
+		br.Versioner = v
+	}
+}
+
+// WithFilesystem makes Broom and its folders read/write through fsys instead
+// of the default OSFilesystem; pass a FakeFilesystem in tests to exercise
+// folder behavior without touching disk.
+func WithFilesystem(fsys Filesystem) BroomOption {
+	return func(br *Broom) {
+		br.Filesystem = fsys
+	}
+}
+
+// WithGlobalMaxSize caps the combined CurrentSize of every managed folder at
+// size; see Broom.GlobalMaxSize, including the caveat about folders that
+// have never been initialized.
+func WithGlobalMaxSize(size Size) BroomOption {
+	return func(br *Broom) {
+		br.GlobalMaxSize = size
+	}
+}
+
 // NewBroom creates and returns a new Broom instance that sweeps folders
 // at the given sweepTime interval. The default RemovingStrategy is used initially.
-func NewBroom(sweepTime time.Duration) *Broom {
+func NewBroom(sweepTime time.Duration, opts ...BroomOption) *Broom {
 	broom := &Broom{
-		operationQueue:   make(chan broomOperation, 10),
-		folders:          make(map[string]*BroomFolder),
-		RemovingStrategy: DEFAULT_REMOVING_STRATEGY,
-		sweepTime:        sweepTime,
+		operationQueue:         make(chan broomOperation, 10),
+		folders:                make(map[string]*BroomFolder),
+		RemovingStrategy:       DEFAULT_REMOVING_STRATEGY,
+		sweepTime:              sweepTime,
+		Filesystem:             OSFilesystem{},
+		GlobalRemovingStrategy: DEFAULT_GLOBAL_REMOVING_STRATEGY,
+	}
+	for _, opt := range opts {
+		opt(broom)
 	}
 	broom.SetStartFunction(func(ctx context.Context) any {
 		broom.loop(ctx)
@@ -60,6 +115,18 @@ func NewBroom(sweepTime time.Duration) *Broom {
 	return broom
 }
 
+// SetVersioner changes the Versioner consulted by folder checks. Pass nil to
+// go back to deleting victim files outright.
+func (br *Broom) SetVersioner(v Versioner) {
+	br.Versioner = v
+}
+
+// SetFilesystem changes the Filesystem folders read/write through. Pass nil
+// to go back to OSFilesystem.
+func (br *Broom) SetFilesystem(fsys Filesystem) {
+	br.Filesystem = fsys
+}
+
 // Run starts the broom background process that handles folder cleanup.
 // If it is already running, Run does nothing.
 func (br *Broom) Start() error {
@@ -70,7 +137,8 @@ func (br *Broom) Start() error {
 	// wait until goroutine has started by sending a ping operation
 	op := broomOperation{
 		op:  OperationPing,
-		sig: make(chan broomOperationResponse),
+		sig: make(chan broomOperationResponse, 1),
+		ctx: context.Background(),
 	}
 	br.operationQueue <- op
 	<-op.sig
@@ -84,10 +152,37 @@ func (br *Broom) Stop() error {
 	return br.Startable.Stop()
 }
 
+// submit sends op on the operation queue and waits for its response. ctx is
+// honored on both ends: a send that can't be queued immediately (the queue
+// is full) and a response that isn't ready yet both unblock as soon as ctx
+// is done, returning ctx.Err().
+func (br *Broom) submit(ctx context.Context, op broomOperation) (broomOperationResponse, error) {
+	op.ctx = ctx
+	select {
+	case br.operationQueue <- op:
+	case <-ctx.Done():
+		return broomOperationResponse{}, ctx.Err()
+	}
+	select {
+	case x := <-op.sig:
+		return x, nil
+	case <-ctx.Done():
+		return broomOperationResponse{}, ctx.Err()
+	}
+}
+
 // AddFolder adds a folder to the broom management system with the specified
 // location and maximum size. Returns an error if the broom is not started or
-// if the folder already exists.
+// if the folder already exists. It is a thin wrapper around AddFolderCtx
+// using context.Background().
 func (br *Broom) AddFolder(location string, maxSize Size) error {
+	return br.AddFolderCtx(context.Background(), location, maxSize)
+}
+
+// AddFolderCtx is AddFolder with a caller-supplied context; a cancelled or
+// timed-out ctx unblocks the caller immediately even if the operation queue
+// is full or the sweep loop is busy.
+func (br *Broom) AddFolderCtx(ctx context.Context, location string, maxSize Size) error {
 	if !br.IsStarted() {
 		return ErrNotStarted
 	}
@@ -97,16 +192,25 @@ func (br *Broom) AddFolder(location string, maxSize Size) error {
 			Location: location,
 			MaxSize:  maxSize,
 		},
-		sig: make(chan broomOperationResponse),
+		sig: make(chan broomOperationResponse, 1),
+	}
+	x, err := br.submit(ctx, op)
+	if err != nil {
+		return err
 	}
-	br.operationQueue <- op
-	x := <-op.sig
 	return x.err
-
 }
 
-// scan and recheck folder without waiting for interval
+// RecheckFolder scans and rechecks a folder without waiting for the sweep
+// interval. It is a thin wrapper around RecheckFolderCtx using
+// context.Background().
 func (br *Broom) RecheckFolder(location string) error {
+	return br.RecheckFolderCtx(context.Background(), location)
+}
+
+// RecheckFolderCtx is RecheckFolder with a caller-supplied context; a
+// cancelled or timed-out ctx also aborts the rescan it triggered.
+func (br *Broom) RecheckFolderCtx(ctx context.Context, location string) error {
 	if !br.IsStarted() {
 		return ErrNotStarted
 	}
@@ -115,18 +219,54 @@ func (br *Broom) RecheckFolder(location string) error {
 		folder: BroomFolder{
 			Location: location,
 		},
-		sig: make(chan broomOperationResponse),
+		sig: make(chan broomOperationResponse, 1),
+	}
+	x, err := br.submit(ctx, op)
+	if err != nil {
+		return err
 	}
-	br.operationQueue <- op
-
-	x := <-op.sig
 	return x.err
+}
 
+// SetFolderIgnores replaces the programmatic ignore patterns (see
+// BroomFolder.SetIgnores) on a folder managed by br. Unlike calling
+// SetIgnores directly on a BroomFolder returned by GetFolder, this mutates
+// the live folder, since GetFolder hands back a copy. It is a thin wrapper
+// around SetFolderIgnoresCtx using context.Background().
+func (br *Broom) SetFolderIgnores(location string, patterns []string) error {
+	return br.SetFolderIgnoresCtx(context.Background(), location, patterns)
+}
+
+// SetFolderIgnoresCtx is SetFolderIgnores with a caller-supplied context.
+func (br *Broom) SetFolderIgnoresCtx(ctx context.Context, location string, patterns []string) error {
+	if !br.IsStarted() {
+		return ErrNotStarted
+	}
+	op := broomOperation{
+		op: OperationSetIgnores,
+		folder: BroomFolder{
+			Location:    location,
+			ignoreLines: patterns,
+		},
+		sig: make(chan broomOperationResponse, 1),
+	}
+	x, err := br.submit(ctx, op)
+	if err != nil {
+		return err
+	}
+	return x.err
 }
 
 // RemoveFolder removes a folder from the broom management system by location.
-// Returns an error if the broom is not started or if the folder does not exist.
+// Returns an error if the broom is not started or if the folder does not
+// exist. It is a thin wrapper around RemoveFolderCtx using
+// context.Background().
 func (br *Broom) RemoveFolder(location string) error {
+	return br.RemoveFolderCtx(context.Background(), location)
+}
+
+// RemoveFolderCtx is RemoveFolder with a caller-supplied context.
+func (br *Broom) RemoveFolderCtx(ctx context.Context, location string) error {
 	if !br.IsStarted() {
 		return ErrNotStarted
 	}
@@ -135,19 +275,26 @@ func (br *Broom) RemoveFolder(location string) error {
 		folder: BroomFolder{
 			Location: location,
 		},
-		sig: make(chan broomOperationResponse),
+		sig: make(chan broomOperationResponse, 1),
+	}
+	x, err := br.submit(ctx, op)
+	if err != nil {
+		return err
 	}
-	br.operationQueue <- op
-
-	x := <-op.sig
 	return x.err
-
 }
 
 // GetFolder retrieves information about a managed folder by location.
 // Returns the folder details or an error if the broom is not started or
-// the folder is not found.
+// the folder is not found. It is a thin wrapper around GetFolderCtx using
+// context.Background().
 func (br *Broom) GetFolder(location string) (BroomFolder, error) {
+	return br.GetFolderCtx(context.Background(), location)
+}
+
+// GetFolderCtx is GetFolder with a caller-supplied context; a cancelled or
+// timed-out ctx also aborts the lazy initialize/scan it may trigger.
+func (br *Broom) GetFolderCtx(ctx context.Context, location string) (BroomFolder, error) {
 	if !br.IsStarted() {
 		return BroomFolder{}, ErrNotStarted
 	}
@@ -156,11 +303,11 @@ func (br *Broom) GetFolder(location string) (BroomFolder, error) {
 		folder: BroomFolder{
 			Location: location,
 		},
-		sig: make(chan broomOperationResponse),
+		sig: make(chan broomOperationResponse, 1),
+	}
+	x, err := br.submit(ctx, op)
+	if err != nil {
+		return BroomFolder{}, err
 	}
-	br.operationQueue <- op
-
-	x := <-op.sig
 	return x.data, x.err
-
 }
@@ -88,3 +88,45 @@ func TestRealFileDeletionAfterSizeLimit(t *testing.T) {
 
 	// Optionally check which files remain or were deleted
 }
+
+// TestSetFolderIgnoresAppliesToManagedFolder guards against regressing to a
+// SetIgnores call that only mutates the BroomFolder copy GetFolder hands
+// back: SetFolderIgnores must reach the live folder stored in br.folders so
+// a later rescan actually excludes the ignored file.
+func TestSetFolderIgnoresAppliesToManagedFolder(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "broom_setignores_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := createFile(filepath.Join(tmpDir, "keep.txt"), 1*Byte); err != nil {
+		t.Fatal(err)
+	}
+	if err := createFile(filepath.Join(tmpDir, "skip.txt"), 1*Byte); err != nil {
+		t.Fatal(err)
+	}
+
+	br := NewBroom(1 * time.Hour)
+	br.Start()
+	defer br.Stop()
+
+	if err := br.AddFolder(tmpDir, 1*MByte); err != nil {
+		t.Fatalf("failed to add folder: %v", err)
+	}
+
+	if err := br.SetFolderIgnores(tmpDir, []string{"skip.txt"}); err != nil {
+		t.Fatalf("SetFolderIgnores failed: %v", err)
+	}
+	if err := br.RecheckFolder(tmpDir); err != nil {
+		t.Fatalf("RecheckFolder failed: %v", err)
+	}
+
+	f, err := br.GetFolder(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to get folder: %v", err)
+	}
+	if f.CurrentSize != 1*Byte {
+		t.Fatalf("CurrentSize = %v, want %v (skip.txt should be excluded)", f.CurrentSize, 1*Byte)
+	}
+}
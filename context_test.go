@@ -0,0 +1,77 @@
+package broom
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSubmitUnblocksOnCancelInsteadOfDeadlocking(t *testing.T) {
+	// Unbuffered queue with nothing ever reading from it: the send case of
+	// submit's first select can never become ready, so this only returns if
+	// the ctx.Done() case fires instead of hanging forever.
+	br := &Broom{operationQueue: make(chan broomOperation)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := br.submit(ctx, broomOperation{
+		op:  OperationPing,
+		sig: make(chan broomOperationResponse, 1),
+	})
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestSubmitUnblocksOnCancelWaitingForResponse(t *testing.T) {
+	// Buffered queue accepts the send, but nothing ever drains it to produce
+	// a response: only the ctx.Done() case of the second select can fire.
+	br := &Broom{operationQueue: make(chan broomOperation, 1)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := br.submit(ctx, broomOperation{
+			op:  OperationPing,
+			sig: make(chan broomOperationResponse, 1),
+		})
+		done <- err
+	}()
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+// TestAddFolderCtxWiresParent exercises the public API end-to-end:
+// AddFolderCtx stores the folder, and GetFolderCtx lazily initializes it,
+// which dereferences bf.parent inside scan/check/fetchMetadata. A folder
+// added any other way than through handleQueue's OperationAdd case would
+// panic here with a nil parent.
+func TestAddFolderCtxWiresParent(t *testing.T) {
+	fsys := NewFakeFilesystem()
+	fsys.AddDir("/virtual/ctxfolder")
+	fsys.AddFile("/virtual/ctxfolder/a.bin", 10*Byte, time.Unix(1000, 0))
+
+	br := NewBroom(time.Hour, WithFilesystem(fsys))
+	if err := br.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer br.Stop()
+
+	ctx := context.Background()
+	if err := br.AddFolderCtx(ctx, "/virtual/ctxfolder", 1*MByte); err != nil {
+		t.Fatalf("AddFolderCtx failed: %v", err)
+	}
+
+	got, err := br.GetFolderCtx(ctx, "/virtual/ctxfolder")
+	if err != nil {
+		t.Fatalf("GetFolderCtx failed: %v", err)
+	}
+	if got.CurrentSize != 10*Byte {
+		t.Fatalf("expected CurrentSize 10B, got %v", got.CurrentSize)
+	}
+}
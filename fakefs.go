@@ -0,0 +1,210 @@
+package broom
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// fakeEntry is one node in a FakeFilesystem's in-memory tree.
+type fakeEntry struct {
+	isDir    bool
+	size     Size
+	createAt time.Time
+	updateAt time.Time
+	content  []byte
+}
+
+// FakeFilesystem is an in-memory Filesystem for tests: file sizes, creation
+// times, and fsnotify events are all programmable, so BroomFolder behavior
+// can be exercised without touching disk. The zero value is not usable; call
+// NewFakeFilesystem.
+type FakeFilesystem struct {
+	mu       sync.Mutex
+	entries  map[string]*fakeEntry
+	watchers map[string][]*fakeWatcher
+}
+
+// NewFakeFilesystem returns an empty FakeFilesystem.
+func NewFakeFilesystem() *FakeFilesystem {
+	return &FakeFilesystem{
+		entries:  map[string]*fakeEntry{},
+		watchers: map[string][]*fakeWatcher{},
+	}
+}
+
+// AddDir registers path as a directory.
+func (fs *FakeFilesystem) AddDir(path string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.entries[filepath.Clean(path)] = &fakeEntry{isDir: true}
+}
+
+// AddFile registers path as a file of the given size with createAt as its
+// birth time. Ancestor directories don't need to be added separately; they
+// show up in ReadDir implicitly from the presence of deeper paths.
+func (fs *FakeFilesystem) AddFile(path string, size Size, createAt time.Time) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.entries[filepath.Clean(path)] = &fakeEntry{size: size, createAt: createAt, updateAt: createAt}
+}
+
+// SetFileContent attaches data to path as the bytes ReadFile returns for it.
+// AddFile alone registers size/time metadata only, with no content; tests
+// that need loadIgnore or other ReadFile-based behavior to see real bytes
+// (e.g. a fake .broomignore) must call this too.
+func (fs *FakeFilesystem) SetFileContent(path string, data []byte) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	path = filepath.Clean(path)
+	entry, ok := fs.entries[path]
+	if !ok {
+		return
+	}
+	entry.content = append([]byte(nil), data...)
+}
+
+// RemoveFile deletes path from the tree directly, without notifying
+// watchers; pair it with Emit to simulate the fsnotify event a real
+// filesystem would also have produced.
+func (fs *FakeFilesystem) RemoveFile(path string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	delete(fs.entries, filepath.Clean(path))
+}
+
+// Emit pushes event to every Watcher currently watching folder, simulating
+// the fsnotify notification a real filesystem would have delivered.
+func (fs *FakeFilesystem) Emit(folder string, event fsnotify.Event) {
+	fs.mu.Lock()
+	watchers := append([]*fakeWatcher(nil), fs.watchers[filepath.Clean(folder)]...)
+	fs.mu.Unlock()
+	for _, w := range watchers {
+		w.events <- event
+	}
+}
+
+func (fs *FakeFilesystem) ReadDir(dir string) ([]DirEntry, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	dir = filepath.Clean(dir)
+	prefix := dir + string(filepath.Separator)
+	seen := map[string]bool{}
+	var out []DirEntry
+	for path, entry := range fs.entries {
+		if path == dir || !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(path, prefix)
+		name, _, hasMore := strings.Cut(rest, string(filepath.Separator))
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, DirEntry{Name: name, IsDir: entry.isDir || hasMore})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (fs *FakeFilesystem) Stat(path string) (FileInfo, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	path = filepath.Clean(path)
+	entry, ok := fs.entries[path]
+	if !ok {
+		return FileInfo{}, fmt.Errorf("fakefs: %s: no such file or directory", path)
+	}
+	return FileInfo{
+		Name:     filepath.Base(path),
+		Size:     entry.size,
+		IsDir:    entry.isDir,
+		CreateAt: entry.createAt,
+		UpdateAt: entry.updateAt,
+	}, nil
+}
+
+func (fs *FakeFilesystem) Remove(path string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	path = filepath.Clean(path)
+	if _, ok := fs.entries[path]; !ok {
+		return fmt.Errorf("fakefs: %s: no such file or directory", path)
+	}
+	delete(fs.entries, path)
+	return nil
+}
+
+// MkdirAll registers dir as a directory; FakeFilesystem directories are
+// otherwise implicit from the presence of deeper paths, so this just makes
+// an empty dir visible to ReadDir before any file is added under it.
+func (fs *FakeFilesystem) MkdirAll(dir string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	dir = filepath.Clean(dir)
+	if _, ok := fs.entries[dir]; !ok {
+		fs.entries[dir] = &fakeEntry{isDir: true}
+	}
+	return nil
+}
+
+func (fs *FakeFilesystem) ReadFile(path string) ([]byte, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	path = filepath.Clean(path)
+	entry, ok := fs.entries[path]
+	if !ok {
+		return nil, fmt.Errorf("fakefs: %s: no such file or directory", path)
+	}
+	return append([]byte(nil), entry.content...), nil
+}
+
+func (fs *FakeFilesystem) Rename(oldpath, newpath string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	entry, ok := fs.entries[oldpath]
+	if !ok {
+		return fmt.Errorf("fakefs: %s: no such file or directory", oldpath)
+	}
+	delete(fs.entries, oldpath)
+	fs.entries[newpath] = entry
+	return nil
+}
+
+// Watch registers and returns a fakeWatcher for path; Emit delivers events to
+// it exactly like the real fsnotify watcher would.
+func (fs *FakeFilesystem) Watch(path string) (Watcher, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	w := &fakeWatcher{events: make(chan fsnotify.Event, 16), errors: make(chan error, 1)}
+	path = filepath.Clean(path)
+	fs.watchers[path] = append(fs.watchers[path], w)
+	return w, nil
+}
+
+// fakeWatcher is the Watcher returned by FakeFilesystem.Watch.
+type fakeWatcher struct {
+	events chan fsnotify.Event
+	errors chan error
+}
+
+func (w *fakeWatcher) Events() <-chan fsnotify.Event { return w.events }
+func (w *fakeWatcher) Errors() <-chan error          { return w.errors }
+func (w *fakeWatcher) Close() error {
+	close(w.events)
+	return nil
+}
@@ -0,0 +1,122 @@
+package broom
+
+import (
+	"os"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DirEntry is the subset of os.DirEntry that Filesystem implementations need
+// to report back to collectFiles.
+type DirEntry struct {
+	Name  string
+	IsDir bool
+}
+
+// FileInfo is the subset of file metadata broom cares about, decoupled from
+// os.FileInfo so FakeFilesystem can report programmable sizes and birth
+// times without any file actually existing on disk.
+type FileInfo struct {
+	Name     string
+	Size     Size
+	IsDir    bool
+	CreateAt time.Time
+	UpdateAt time.Time
+}
+
+// Watcher reports filesystem change events for a single watched folder.
+type Watcher interface {
+	Events() <-chan fsnotify.Event
+	Errors() <-chan error
+	Close() error
+}
+
+// Filesystem abstracts the os/filepath/times calls scattered through
+// collectFiles, DeleteFiles, getCreateTime and validateFolder, along with the
+// fsnotify watch used by BroomFolder. OSFilesystem preserves prior behavior
+// exactly; FakeFilesystem lets folders be driven entirely in memory in tests.
+type Filesystem interface {
+	ReadDir(dir string) ([]DirEntry, error)
+	Stat(path string) (FileInfo, error)
+	Remove(path string) error
+	Rename(oldpath, newpath string) error
+	// MkdirAll creates dir and any missing parents, matching os.MkdirAll.
+	// Versioner implementations use it to lay out archive directories
+	// before moving victim files into them.
+	MkdirAll(dir string) error
+	// ReadFile returns path's full contents, matching os.ReadFile. It exists
+	// for small config-like reads such as .broomignore, not bulk file I/O.
+	ReadFile(path string) ([]byte, error)
+	Watch(path string) (Watcher, error)
+}
+
+// OSFilesystem is the default Filesystem, backed by the os package for file
+// access and fsnotify for change notifications.
+type OSFilesystem struct{}
+
+func (OSFilesystem) ReadDir(dir string) ([]DirEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]DirEntry, len(entries))
+	for i, e := range entries {
+		out[i] = DirEntry{Name: e.Name(), IsDir: e.IsDir()}
+	}
+	return out, nil
+}
+
+func (OSFilesystem) Stat(path string) (FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	return FileInfo{
+		Name:     info.Name(),
+		Size:     Size(info.Size()),
+		IsDir:    info.IsDir(),
+		CreateAt: getCreateTime(info),
+		UpdateAt: info.ModTime(),
+	}, nil
+}
+
+func (OSFilesystem) Remove(path string) error {
+	return os.Remove(path)
+}
+
+func (OSFilesystem) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (OSFilesystem) MkdirAll(dir string) error {
+	return os.MkdirAll(dir, 0755)
+}
+
+func (OSFilesystem) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// Watch creates an fsnotify watcher on path, matching the NewWatcher+Add
+// pair BroomFolder.initialize used to perform directly.
+func (OSFilesystem) Watch(path string) (Watcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Add(path); err != nil {
+		w.Close()
+		return nil, err
+	}
+	return osWatcher{w}, nil
+}
+
+// osWatcher adapts *fsnotify.Watcher's Events/Errors fields to the Watcher
+// interface's method set.
+type osWatcher struct {
+	w *fsnotify.Watcher
+}
+
+func (o osWatcher) Events() <-chan fsnotify.Event { return o.w.Events }
+func (o osWatcher) Errors() <-chan error          { return o.w.Errors }
+func (o osWatcher) Close() error                  { return o.w.Close() }
@@ -0,0 +1,110 @@
+package broom
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// TestOSFilesystemStatFollowsSymlinkedDirectory guards against regressing to
+// os.Lstat, which would make validateFolder reject a BroomFolder.Location
+// that's a symlink to a directory (a realistic setup for mounted volumes).
+func TestOSFilesystemStatFollowsSymlinkedDirectory(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "broom_symlink_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	realDir := filepath.Join(tmpDir, "real")
+	if err := os.Mkdir(realDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(tmpDir, "link")
+	if err := os.Symlink(realDir, link); err != nil {
+		t.Skipf("symlinks unsupported on this platform: %v", err)
+	}
+
+	info, err := (OSFilesystem{}).Stat(link)
+	if err != nil {
+		t.Fatalf("Stat(symlink-to-dir) failed: %v", err)
+	}
+	if !info.IsDir {
+		t.Errorf("expected Stat to follow the symlink and report IsDir, got %+v", info)
+	}
+
+	if err := validateFolder(OSFilesystem{}, link); err != nil {
+		t.Errorf("validateFolder rejected a symlinked directory: %v", err)
+	}
+}
+
+func TestFakeFilesystemDrivesFolderScanAndCheck(t *testing.T) {
+	fsys := NewFakeFilesystem()
+	fsys.AddDir("/virtual/folder")
+	fsys.AddFile("/virtual/folder/old.bin", 600*KByte, time.Unix(1000, 0))
+	fsys.AddFile("/virtual/folder/new.bin", 300*KByte, time.Unix(2000, 0))
+
+	br := &Broom{
+		folders:          make(map[string]*BroomFolder),
+		RemovingStrategy: DEFAULT_REMOVING_STRATEGY,
+		Filesystem:       fsys,
+	}
+	bf := &BroomFolder{Location: "/virtual/folder", MaxSize: 400 * KByte, parent: br}
+
+	if err := bf.initialize(context.Background()); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	defer bf.deInit()
+
+	if bf.CurrentSize > bf.MaxSize {
+		t.Fatalf("expected check() to shrink CurrentSize to <= %v, got %v", bf.MaxSize, bf.CurrentSize)
+	}
+	if _, err := fsys.Stat("/virtual/folder/old.bin"); err == nil {
+		t.Errorf("expected the oldest file to have been removed by check()")
+	}
+	if _, err := fsys.Stat("/virtual/folder/new.bin"); err != nil {
+		t.Errorf("expected the newest file to survive, got stat error: %v", err)
+	}
+}
+
+func TestFakeFilesystemEmitDrivesDebouncedRescan(t *testing.T) {
+	fsys := NewFakeFilesystem()
+	fsys.AddDir("/virtual/folder2")
+
+	br := &Broom{
+		folders:          make(map[string]*BroomFolder),
+		RemovingStrategy: DEFAULT_REMOVING_STRATEGY,
+		Filesystem:       fsys,
+		operationQueue:   make(chan broomOperation, 10),
+	}
+	bf := &BroomFolder{Location: "/virtual/folder2", parent: br}
+	br.folders[bf.Location] = bf
+
+	if err := bf.initialize(context.Background()); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	defer bf.deInit()
+
+	if bf.CurrentSize != 0 {
+		t.Fatalf("expected empty folder to start at size 0, got %v", bf.CurrentSize)
+	}
+
+	path := "/virtual/folder2/created.bin"
+	fsys.AddFile(path, 42*Byte, time.Unix(3000, 0))
+	fsys.Emit(bf.Location, fsnotify.Event{Name: path, Op: fsnotify.Create})
+
+	select {
+	case op := <-br.operationQueue:
+		br.handleQueue(op)
+	case <-time.After(shortPullIntv * 3):
+		t.Fatal("timed out waiting for the injected fsnotify event to trigger a rescan")
+	}
+
+	if bf.CurrentSize != 42*Byte {
+		t.Fatalf("expected CurrentSize 42 after the simulated create, got %v", bf.CurrentSize)
+	}
+}
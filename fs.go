@@ -2,6 +2,7 @@ package broom
 
 import (
 	"container/list"
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -10,8 +11,6 @@ import (
 	"strings"
 	"syscall"
 	"time"
-
-	"github.com/djherbis/times"
 )
 
 type File struct {
@@ -22,6 +21,13 @@ type File struct {
 	CreateAt time.Time
 	UpdateAt time.Time
 	Metadata map[string]any
+
+	// Ignored and Deletable come from a "(?d)" ignore pattern: the file is
+	// hidden from listings but, being Deletable, remains eligible for
+	// removal. Plain ignore matches never reach this far — collectFiles
+	// skips them outright.
+	Ignored   bool
+	Deletable bool
 }
 
 func (f File) String() string {
@@ -39,41 +45,42 @@ func (f *File) GetExtension() string {
 	return filepath.Ext(f.Path)
 }
 
+// ListFiles lists folderPath's immediate children with their size and
+// creation time. It is a thin wrapper around listFiles using OSFilesystem;
+// BroomFolder's own scanning goes through collectFiles with the folder's
+// configured Filesystem instead.
 func ListFiles(folderPath string) ([]File, error) {
-	entries, err := os.ReadDir(folderPath)
+	return listFiles(OSFilesystem{}, folderPath)
+}
+
+// listFiles lists dir's immediate children via fsys.
+func listFiles(fsys Filesystem, dir string) ([]File, error) {
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	absDir, err := filepath.Abs(dir)
 	if err != nil {
 		return nil, err
 	}
 
 	var files []File
 	for _, entry := range entries {
-		info, err := entry.Info()
-		if err != nil {
-			return nil, err
-		}
+		fullPath := filepath.Join(absDir, entry.Name)
 
-		absPath, err := filepath.Abs(filepath.Join(folderPath, entry.Name()))
+		info, err := fsys.Stat(fullPath)
 		if err != nil {
 			return nil, err
 		}
 
-		t, err := times.Stat(absPath)
-		if err != nil {
-			return nil, err
-		}
-		var date time.Time
-		if t.HasBirthTime() {
-			date = t.BirthTime()
-		} else {
-			date = t.ChangeTime()
-		}
-
 		files = append(files, File{
-			Name:     entry.Name(),
-			Path:     absPath,
-			IsDir:    entry.IsDir(),
-			Size:     Size(info.Size()),
-			CreateAt: date,
+			Name:     entry.Name,
+			Path:     fullPath,
+			IsDir:    entry.IsDir,
+			Size:     info.Size,
+			CreateAt: info.CreateAt,
+			UpdateAt: info.UpdateAt,
 		})
 	}
 	return files, nil
@@ -98,7 +105,7 @@ var DEFAULT_REMOVING_STRATEGY RemovingStrategy = func(
 	// Collect files into a slice for sorting
 	var filesSlice []File
 	for e := allFiles.Front(); e != nil; e = e.Next() {
-		if f, ok := e.Value.(File); ok && !f.IsDir {
+		if f, ok := e.Value.(File); ok && !f.IsDir && !(f.Ignored && !f.Deletable) {
 			filesSlice = append(filesSlice, f)
 		}
 	}
@@ -130,7 +137,7 @@ var NAME_BASED_REMOVING_STRATEGY RemovingStrategy = func(
 	// Collect files into a slice for sorting
 	var filesSlice []File
 	for e := allFiles.Front(); e != nil; e = e.Next() {
-		if f, ok := e.Value.(File); ok && !f.IsDir {
+		if f, ok := e.Value.(File); ok && !f.IsDir && !(f.Ignored && !f.Deletable) {
 			filesSlice = append(filesSlice, f)
 		}
 	}
@@ -153,14 +160,32 @@ var NAME_BASED_REMOVING_STRATEGY RemovingStrategy = func(
 
 	return filesToRemove
 }
+// DeleteFiles removes files from disk. It is a thin wrapper around
+// DeleteFilesCtx using context.Background().
 func DeleteFiles(files []File) error {
+	return DeleteFilesCtx(context.Background(), files)
+}
+
+// DeleteFilesCtx removes files from disk, aborting before the next removal
+// once ctx is done. It is a thin wrapper around deleteFiles using
+// OSFilesystem; BroomFolder callers go through deleteFiles directly so a
+// folder's own Filesystem (e.g. a FakeFilesystem in tests) is honored.
+func DeleteFilesCtx(ctx context.Context, files []File) error {
+	return deleteFiles(ctx, OSFilesystem{}, files)
+}
+
+// deleteFiles removes files via fsys, aborting before the next removal once
+// ctx is done.
+func deleteFiles(ctx context.Context, fsys Filesystem, files []File) error {
 	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if file.IsDir {
 			continue // skip directories for safety
 		}
 
-		err := os.Remove(file.Path)
-		if err != nil {
+		if err := fsys.Remove(file.Path); err != nil {
 			return fmt.Errorf("failed to delete %s: %w", file.Path, err)
 		}
 	}
@@ -173,11 +198,12 @@ func DeleteFiles(files []File) error {
 
 // BuildFileList scans a folder (recursive if flag=true), filters, sorts, and returns a linked list of files.
 func BuildFileList(folder string, extensions []string, recursive bool) (*list.List, error) {
-	if err := validateFolder(folder); err != nil {
+	fsys := OSFilesystem{}
+	if err := validateFolder(fsys, folder); err != nil {
 		return nil, err
 	}
 
-	files, err := collectFiles(folder, extensions, recursive)
+	files, err := collectFiles(context.Background(), fsys, folder, extensions, recursive, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -192,33 +218,60 @@ func BuildFileList(folder string, extensions []string, recursive bool) (*list.Li
 // ────────────────────────────────
 
 // validateFolder ensures path exists and is a directory.
-func validateFolder(folder string) error {
-	info, err := os.Stat(folder)
+func validateFolder(fsys Filesystem, folder string) error {
+	info, err := fsys.Stat(folder)
 	if err != nil {
 		return fmt.Errorf("cannot access folder: %w", err)
 	}
-	if !info.IsDir() {
+	if !info.IsDir {
 		return errors.New("provided path is not a directory")
 	}
 	return nil
 }
 
-// collectFiles walks through a directory and collects files matching given extensions
-func collectFiles(folder string, extensions []string, recursive bool) ([]File, error) {
+// collectFiles walks through a directory and collects files matching given
+// extensions. ignore (may be nil) is consulted to skip excluded entries
+// during the walk; entries matched by a "(?d)" pattern are still collected,
+// but flagged File.Ignored/Deletable so callers can hide them from listings.
+// The walk aborts with ctx.Err() once ctx is done.
+func collectFiles(ctx context.Context, fsys Filesystem, folder string, extensions []string, recursive bool, ignore *Ignore) ([]File, error) {
+	return collectFilesRoot(ctx, fsys, folder, folder, extensions, recursive, ignore)
+}
+
+// collectFilesRoot is collectFiles with the folder root threaded through
+// recursive calls, so ignore patterns can be matched relative to it.
+func collectFilesRoot(ctx context.Context, fsys Filesystem, root, dir string, extensions []string, recursive bool, ignore *Ignore) ([]File, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	var files []File
 
-	entries, err := os.ReadDir(folder)
+	entries, err := fsys.ReadDir(dir)
 	if err != nil {
 		return nil, fmt.Errorf("cannot read directory: %w", err)
 	}
 
 	for _, entry := range entries {
-		fullPath := filepath.Join(folder, entry.Name())
+		if err := ctx.Err(); err != nil {
+			return files, err
+		}
+
+		fullPath := filepath.Join(dir, entry.Name)
 
-		if entry.IsDir() {
+		rel, err := filepath.Rel(root, fullPath)
+		if err != nil {
+			rel = entry.Name
+		}
+		ignored, deletable := ignore.Match(rel, entry.IsDir)
+		if ignored && !deletable {
+			continue
+		}
+
+		if entry.IsDir {
 			if recursive {
 				// Recursively collect from subdirectory
-				subFiles, err := collectFiles(fullPath, extensions, recursive)
+				subFiles, err := collectFilesRoot(ctx, fsys, root, fullPath, extensions, recursive, ignore)
 				if err != nil {
 					continue // skip inaccessible subfolders
 				}
@@ -227,23 +280,25 @@ func collectFiles(folder string, extensions []string, recursive bool) ([]File, e
 			continue
 		}
 
-		if !hasAllowedExtension(entry.Name(), extensions) {
+		if !hasAllowedExtension(entry.Name, extensions) {
 			continue
 		}
 
-		info, err := entry.Info()
+		info, err := fsys.Stat(fullPath)
 		if err != nil {
 			continue // skip unreadable files
 		}
 
 		f := File{
-			Path:     fullPath,
-			Name:     entry.Name(),
-			IsDir:    false,
-			Size:     Size(info.Size()),
-			CreateAt: getCreateTime(info),
-			UpdateAt: info.ModTime(),
-			Metadata: map[string]any{},
+			Path:      fullPath,
+			Name:      entry.Name,
+			IsDir:     false,
+			Size:      info.Size,
+			CreateAt:  info.CreateAt,
+			UpdateAt:  info.UpdateAt,
+			Metadata:  map[string]any{},
+			Ignored:   ignored,
+			Deletable: deletable,
 		}
 		files = append(files, f)
 	}
@@ -0,0 +1,223 @@
+package broom
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+)
+
+// checkGlobal runs GlobalRemovingStrategy across every managed folder once
+// their combined CurrentSize exceeds GlobalMaxSize, mirroring the
+// Versioner/DeleteFiles + onRemoveCb dispatch that BroomFolder.check uses
+// per folder. It is called once per loop iteration, after that tick's queued
+// operations (and any per-folder check they triggered) have been handled.
+// CurrentSize is only ever touched from the single goroutine driving loop,
+// so updating it here needs no extra synchronization.
+func (br *Broom) checkGlobal(ctx context.Context) error {
+	if br.GlobalMaxSize == 0 || br.GlobalRemovingStrategy == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var total Size
+	for _, bf := range br.folders {
+		if bf != nil && bf.isInitialized() {
+			total += bf.CurrentSize
+		}
+	}
+	if total <= br.GlobalMaxSize {
+		return nil
+	}
+
+	victims := br.GlobalRemovingStrategy(br.folders, total-br.GlobalMaxSize)
+	for bf, files := range victims {
+		if len(files) == 0 {
+			continue
+		}
+		var err error
+		if br.Versioner != nil {
+			err = br.Versioner.Archive(bf, files)
+		} else {
+			err = deleteFiles(ctx, bf.fs(), files)
+		}
+		if br.onRemoveCb != nil {
+			br.onRemoveCb(bf, files)
+		}
+		if err != nil {
+			return err
+		}
+		bf.CurrentSize -= calculateFolderSize(files)
+	}
+	return nil
+}
+
+// globalHeapItem pairs a candidate File with the folder it came from, so a
+// single min-heap can rank victims across every managed folder together.
+type globalHeapItem struct {
+	file   File
+	folder *BroomFolder
+}
+
+// globalFileHeap is a container/heap min-heap ordered by File.CreateAt
+// ascending, so the oldest file across all folders pops first.
+type globalFileHeap []*globalHeapItem
+
+func (h globalFileHeap) Len() int { return len(h) }
+func (h globalFileHeap) Less(i, j int) bool {
+	return h[i].file.CreateAt.Before(h[j].file.CreateAt)
+}
+func (h globalFileHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *globalFileHeap) Push(x any)   { *h = append(*h, x.(*globalHeapItem)) }
+func (h *globalFileHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// eligibleFolderFiles returns folder's non-directory, non-ignored (unless
+// deletable) files, the same eligibility rule DEFAULT_REMOVING_STRATEGY
+// applies per folder.
+func eligibleFolderFiles(bf *BroomFolder) []File {
+	if bf == nil || bf.list == nil {
+		return nil
+	}
+	var files []File
+	for e := bf.list.Front(); e != nil; e = e.Next() {
+		if f, ok := e.Value.(File); ok && !f.IsDir && !(f.Ignored && !f.Deletable) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// DEFAULT_GLOBAL_REMOVING_STRATEGY merges every folder's eligible files into
+// a single min-heap keyed by CreateAt and pops the globally oldest files
+// first until needReduce bytes are freed, skipping any victim that would
+// drain its folder below MinRetain.
+var DEFAULT_GLOBAL_REMOVING_STRATEGY GlobalRemovingStrategy = func(
+	folders map[string]*BroomFolder,
+	needReduce Size,
+) map[*BroomFolder][]File {
+	h := &globalFileHeap{}
+	remaining := make(map[*BroomFolder]Size, len(folders))
+	for _, bf := range folders {
+		files := eligibleFolderFiles(bf)
+		if files == nil {
+			continue
+		}
+		remaining[bf] = bf.CurrentSize
+		for _, f := range files {
+			heap.Push(h, &globalHeapItem{file: f, folder: bf})
+		}
+	}
+
+	result := map[*BroomFolder][]File{}
+	var freed Size
+	for freed < needReduce && h.Len() > 0 {
+		item := heap.Pop(h).(*globalHeapItem)
+		if remaining[item.folder] < item.folder.MinRetain+item.file.Size {
+			continue // would drain this folder below its floor
+		}
+		remaining[item.folder] -= item.file.Size
+		freed += item.file.Size
+		result[item.folder] = append(result[item.folder], item.file)
+	}
+	return result
+}
+
+// FAIRNESS_WEIGHTED_GLOBAL_REMOVING_STRATEGY splits needReduce across
+// folders proportionally to each folder's share of the total space
+// available above its MinRetain floor, then evicts that folder's own oldest
+// files up to its share. A folder entirely at or under its MinRetain
+// contributes nothing and loses nothing.
+var FAIRNESS_WEIGHTED_GLOBAL_REMOVING_STRATEGY GlobalRemovingStrategy = func(
+	folders map[string]*BroomFolder,
+	needReduce Size,
+) map[*BroomFolder][]File {
+	type share struct {
+		location  string
+		folder    *BroomFolder
+		available Size
+	}
+
+	var shares []share
+	var totalAvailable Size
+	for loc, bf := range folders {
+		if bf == nil || bf.list == nil || bf.CurrentSize <= bf.MinRetain {
+			continue
+		}
+		available := bf.CurrentSize - bf.MinRetain
+		shares = append(shares, share{location: loc, folder: bf, available: available})
+		totalAvailable += available
+	}
+	// Folders are processed in a fixed order so that the running budget
+	// below is deterministic across runs instead of depending on map
+	// iteration order.
+	sort.Slice(shares, func(i, j int) bool { return shares[i].location < shares[j].location })
+
+	result := map[*BroomFolder][]File{}
+	if totalAvailable == 0 {
+		return result
+	}
+
+	// remaining caps the combined total freed across every folder at
+	// needReduce, mirroring the running total DEFAULT_GLOBAL_REMOVING_STRATEGY
+	// keeps against its single shared heap. Without it, each folder's
+	// proportional target is computed independently, and a folder whose
+	// oldest eligible file overshoots its own target (files can't be split)
+	// doesn't reduce what every other folder still tries to free on top of
+	// that: the strategy degenerates into draining every folder in full.
+	remaining := needReduce
+	for _, s := range shares {
+		if remaining == 0 {
+			break
+		}
+		target := Size(uint64(needReduce) * uint64(s.available) / uint64(totalAvailable))
+		if target > remaining {
+			target = remaining
+		}
+		if target == 0 {
+			continue
+		}
+		victims := oldestFilesUpTo(s.folder, target)
+		if len(victims) == 0 {
+			continue
+		}
+		result[s.folder] = victims
+		if freed := calculateFolderSize(victims); freed >= remaining {
+			remaining = 0
+		} else {
+			remaining -= freed
+		}
+	}
+	return result
+}
+
+// oldestFilesUpTo picks bf's oldest eligible files, in create-time order,
+// until target bytes would be freed or bf.MinRetain would be violated.
+func oldestFilesUpTo(bf *BroomFolder, target Size) []File {
+	candidates := eligibleFolderFiles(bf)
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].CreateAt.Before(candidates[j].CreateAt)
+	})
+
+	remaining := bf.CurrentSize
+	var freed Size
+	var picked []File
+	for _, f := range candidates {
+		if freed >= target {
+			break
+		}
+		if remaining < bf.MinRetain+f.Size {
+			continue
+		}
+		picked = append(picked, f)
+		freed += f.Size
+		remaining -= f.Size
+	}
+	return picked
+}
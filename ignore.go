@@ -0,0 +1,181 @@
+package broom
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ignoreFileName is the per-folder file BroomFolder looks for at its root,
+// in addition to patterns set programmatically via SetIgnores.
+const ignoreFileName = ".broomignore"
+
+// ignorePattern is one compiled line from a .broomignore file or a
+// programmatic pattern passed to BroomFolder.SetIgnores.
+type ignorePattern struct {
+	regex     *regexp.Regexp
+	negate    bool
+	deletable bool
+	dirOnly   bool
+}
+
+// Ignore matches folder-relative paths against a set of gitignore-style
+// patterns. Patterns are evaluated in order and the last match wins,
+// mirroring git's own precedence rules.
+type Ignore struct {
+	patterns []ignorePattern
+}
+
+// ParseIgnore compiles a set of gitignore-style pattern lines:
+//   - blank lines and lines starting with "#" are comments
+//   - a leading "!" negates the pattern (a previously ignored path is unignored)
+//   - a leading "(?d)" marks the pattern deletable: matching files are hidden
+//     from listings but remain eligible for removal to reclaim space
+//   - "!" and "(?d)" may be combined in either order, e.g. "!(?d)pattern";
+//     since Match reports deletable only for patterns that end up ignored,
+//     combining them just unignores the path cleanly instead of leaking the
+//     unstripped "(?d)" text into the glob
+//   - "**" matches across directory separators, a single "*" does not
+//   - a pattern containing "/" other than a trailing one is anchored to the
+//     folder root; otherwise it matches at any depth
+//   - a trailing "/" restricts the pattern to directories
+func ParseIgnore(lines []string) (*Ignore, error) {
+	ig := &Ignore{}
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// "(?d)" and "!" may appear in either order (e.g. "!(?d)pattern" to
+		// un-ignore a path while still flagging it deletable), so strip
+		// whichever comes first and keep going until neither prefix matches.
+		deletable := false
+		negate := false
+		for stripped := true; stripped; {
+			stripped = false
+			if strings.HasPrefix(line, "(?d)") {
+				deletable = true
+				line = line[len("(?d)"):]
+				stripped = true
+			}
+			if strings.HasPrefix(line, "!") {
+				negate = true
+				line = line[1:]
+				stripped = true
+			}
+		}
+
+		anchored := strings.Contains(strings.TrimSuffix(line, "/"), "/")
+		line = strings.TrimPrefix(line, "/")
+		dirOnly := strings.HasSuffix(line, "/")
+		line = strings.TrimSuffix(line, "/")
+
+		re, err := compileGlob(line, anchored)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q: %w", raw, err)
+		}
+
+		ig.patterns = append(ig.patterns, ignorePattern{
+			regex:     re,
+			negate:    negate,
+			deletable: deletable,
+			dirOnly:   dirOnly,
+		})
+	}
+	return ig, nil
+}
+
+// compileGlob turns a single gitignore-style glob into a regexp matched
+// against "/"-separated paths relative to the folder root.
+func compileGlob(pattern string, anchored bool) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; {
+		case c == '*' && i+1 < len(runes) && runes[i+1] == '*':
+			b.WriteString(".*")
+			i++
+			if i+1 < len(runes) && runes[i+1] == '/' {
+				i++
+			}
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("(?:/.*)?$")
+	return regexp.Compile(b.String())
+}
+
+// Match reports whether rel (a "/"-separated path relative to the folder
+// root) is ignored, and if ignored, whether it was marked deletable via a
+// "(?d)" pattern. A nil Ignore matches nothing.
+func (ig *Ignore) Match(rel string, isDir bool) (ignored bool, deletable bool) {
+	if ig == nil {
+		return false, false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, p := range ig.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.regex.MatchString(rel) {
+			ignored = !p.negate
+			deletable = ignored && p.deletable
+		}
+	}
+	return ignored, deletable
+}
+
+// SetIgnores sets programmatic ignore patterns for the folder. They are
+// merged with any .broomignore file found at the folder root the next time
+// the matcher is (re)loaded, i.e. on the next scan.
+func (bf *BroomFolder) SetIgnores(patterns []string) {
+	bf.ignoreLines = patterns
+	bf.ignore = nil
+}
+
+// loadIgnore returns the compiled Ignore matcher for the folder, merging its
+// .broomignore file (if any) with programmatic patterns from SetIgnores. The
+// matcher is cached and only recompiled when .broomignore's mtime changes.
+// Reads go through bf.fs(), so a folder driven entirely by a FakeFilesystem
+// gets .broomignore support too, without ever touching the real disk.
+func (bf *BroomFolder) loadIgnore() *Ignore {
+	fsys := bf.fs()
+	path := filepath.Join(bf.Location, ignoreFileName)
+
+	var modTime time.Time
+	var fileLines []string
+	if info, err := fsys.Stat(path); err == nil {
+		modTime = info.UpdateAt
+		if data, err := fsys.ReadFile(path); err == nil {
+			fileLines = strings.Split(string(data), "\n")
+		}
+	}
+
+	if bf.ignore != nil && modTime.Equal(bf.ignoreFileModTime) {
+		return bf.ignore
+	}
+
+	lines := append(append([]string{}, fileLines...), bf.ignoreLines...)
+	ig, err := ParseIgnore(lines)
+	if err != nil {
+		log.Printf("broom: failed to parse ignore patterns for %s: %v", bf.Location, err)
+		ig = &Ignore{}
+	}
+	bf.ignore = ig
+	bf.ignoreFileModTime = modTime
+	return bf.ignore
+}
@@ -0,0 +1,160 @@
+package broom
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIgnoreMatchBasicAndNegate(t *testing.T) {
+	ig, err := ParseIgnore([]string{
+		"# comment",
+		"*.tmp",
+		"!keep.tmp",
+		"/build",
+		"(?d)cache/**",
+	})
+	if err != nil {
+		t.Fatalf("ParseIgnore failed: %v", err)
+	}
+
+	cases := []struct {
+		rel           string
+		isDir         bool
+		wantIgnored   bool
+		wantDeletable bool
+	}{
+		{"a.tmp", false, true, false},
+		{"keep.tmp", false, false, false},
+		{"build", true, true, false},
+		{"nested/build", true, false, false}, // anchored to root, shouldn't match nested
+		{"cache/old.bin", false, true, true},
+	}
+	for _, c := range cases {
+		ignored, deletable := ig.Match(c.rel, c.isDir)
+		if ignored != c.wantIgnored || deletable != c.wantDeletable {
+			t.Errorf("Match(%q) = (%v, %v), want (%v, %v)", c.rel, ignored, deletable, c.wantIgnored, c.wantDeletable)
+		}
+	}
+}
+
+// TestParseIgnoreRecognizesNegateAndDeletablePrefixesInEitherOrder guards
+// against regressing to order-sensitive prefix stripping: previously only
+// "(?d)!pattern" parsed correctly, while "!(?d)pattern" left the literal
+// "(?d)" text in the glob, corrupting it so the pattern never matched
+// (leaving the path permanently ignored instead of unignored).
+func TestParseIgnoreRecognizesNegateAndDeletablePrefixesInEitherOrder(t *testing.T) {
+	ig, err := ParseIgnore([]string{
+		"cache/**",
+		"!(?d)cache/keep.bin",
+		"(?d)!other/keep.bin",
+	})
+	if err != nil {
+		t.Fatalf("ParseIgnore failed: %v", err)
+	}
+
+	cases := []struct {
+		rel           string
+		wantIgnored   bool
+		wantDeletable bool
+	}{
+		// the negation half of each pattern wins (Match only reports
+		// deletable for a pattern that ends up ignored), so both are simply
+		// unignored rather than staying stuck ignored with a corrupted glob.
+		{"cache/keep.bin", false, false},
+		{"other/keep.bin", false, false},
+	}
+	for _, c := range cases {
+		ignored, deletable := ig.Match(c.rel, false)
+		if ignored != c.wantIgnored || deletable != c.wantDeletable {
+			t.Errorf("Match(%q) = (%v, %v), want (%v, %v)", c.rel, ignored, deletable, c.wantIgnored, c.wantDeletable)
+		}
+	}
+}
+
+func TestCollectFilesSkipsIgnoredUnlessDeletable(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "broom_ignore_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for _, name := range []string{"keep.txt", "skip.tmp", "cache.bin"} {
+		if err := createFile(filepath.Join(tmpDir, name), 1*Byte); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ig, err := ParseIgnore([]string{"*.tmp", "(?d)cache.bin"})
+	if err != nil {
+		t.Fatalf("ParseIgnore failed: %v", err)
+	}
+
+	files, err := collectFiles(context.Background(), OSFilesystem{}, tmpDir, nil, false, ig)
+	if err != nil {
+		t.Fatalf("collectFiles failed: %v", err)
+	}
+
+	byName := map[string]File{}
+	for _, f := range files {
+		byName[f.Name] = f
+	}
+
+	if _, ok := byName["skip.tmp"]; ok {
+		t.Errorf("expected skip.tmp to be excluded from scan")
+	}
+	if _, ok := byName["keep.txt"]; !ok {
+		t.Errorf("expected keep.txt to be collected")
+	}
+	cache, ok := byName["cache.bin"]
+	if !ok {
+		t.Fatalf("expected cache.bin to be collected as hidden-but-deletable")
+	}
+	if !cache.Ignored || !cache.Deletable {
+		t.Errorf("expected cache.bin to be Ignored+Deletable, got %+v", cache)
+	}
+}
+
+func TestLoadIgnoreReadsBroomignoreFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "broom_ignore_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.WriteFile(filepath.Join(tmpDir, ignoreFileName), []byte("*.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bf := &BroomFolder{Location: tmpDir}
+	ig := bf.loadIgnore()
+	ignored, _ := ig.Match("debug.log", false)
+	if !ignored {
+		t.Errorf("expected debug.log to be ignored per .broomignore")
+	}
+
+	// cached matcher is reused while the file is unchanged
+	if bf.loadIgnore() != ig {
+		t.Errorf("expected loadIgnore to return the cached matcher when .broomignore is unchanged")
+	}
+}
+
+// TestLoadIgnoreReadsBroomignoreFromFakeFilesystem exercises loadIgnore
+// through a folder driven entirely by a FakeFilesystem: it must read
+// .broomignore from the fake tree, not fall through to the real disk.
+func TestLoadIgnoreReadsBroomignoreFromFakeFilesystem(t *testing.T) {
+	fsys := NewFakeFilesystem()
+	fsys.AddDir("/virtual/ignorefolder")
+	fsys.AddFile("/virtual/ignorefolder/"+ignoreFileName, 6*Byte, time.Unix(1000, 0))
+	fsys.SetFileContent("/virtual/ignorefolder/"+ignoreFileName, []byte("*.log\n"))
+
+	bf := &BroomFolder{Location: "/virtual/ignorefolder", Filesystem: fsys}
+	ig := bf.loadIgnore()
+
+	ignored, _ := ig.Match("debug.log", false)
+	if !ignored {
+		t.Errorf("expected debug.log to be ignored per the fake .broomignore")
+	}
+}
@@ -49,9 +49,26 @@ type BroomFolder struct {
 	Location    string
 	MaxSize     Size
 	CurrentSize Size
-	list        *list.List
-	parent      *Broom
-	watcher     *fsnotify.Watcher
+	Filesystem  Filesystem
+	// MinRetain floors how far a cross-folder GlobalRemovingStrategy may
+	// drain this folder: it will never pick a victim that would bring
+	// CurrentSize below MinRetain, even if the global quota is still
+	// exceeded afterwards. Unused by per-folder RemovingStrategy.
+	MinRetain Size
+	list      *list.List
+	parent    *Broom
+	watcher   Watcher
+
+	ignoreLines       []string
+	ignore            *Ignore
+	ignoreFileModTime time.Time
+
+	// rescan holds the debounce/timer state for the fsnotify-driven rescan
+	// pipeline. It's a pointer rather than an embedded mutex because
+	// BroomFolder is routinely copied by value (broomOperation.folder,
+	// broomOperationResponse.data) and copying a live sync.Mutex would be
+	// both incorrect and a go vet failure; every copy shares the same state.
+	rescan *rescanState
 }
 
 func (bf BroomFolder) String() string {
@@ -62,20 +79,41 @@ func (bf *BroomFolder) isInitialized() bool {
 	return bf.list != nil
 }
 
+// fs returns the Filesystem this folder reads/writes through: its own
+// override if set, else its parent Broom's, else OSFilesystem as a last
+// resort for folders used outside of a Broom (e.g. in tests).
+func (bf *BroomFolder) fs() Filesystem {
+	if bf.Filesystem != nil {
+		return bf.Filesystem
+	}
+	if bf.parent != nil && bf.parent.Filesystem != nil {
+		return bf.parent.Filesystem
+	}
+	return OSFilesystem{}
+}
+
+// onFolderEvent runs on the folder's dedicated fsnotify watcher goroutine
+// (see watch()), outside the operation-queue serialization that guards the
+// rest of BroomFolder's mutable state. It must not touch bf.ignore /
+// bf.ignoreFileModTime (via loadIgnore) or any other queue-owned field;
+// ignore filtering happens later in applyRescanEvent, on the loop goroutine
+// that owns them. One consequence: churn inside an ignored directory now
+// still arms the debounce timer and dispatches an OperationRescan that gets
+// dropped once applyRescanEvent re-checks it, instead of being filtered out
+// for free here; that's the accepted cost of not reading ignore state off
+// this goroutine.
 func (bf *BroomFolder) onFolderEvent(event fsnotify.Event) {
 	switch {
 	case event.Has(fsnotify.Create):
 		log.Printf("[+] New file: %s\n", event.Name)
-		// bf.Rescan()
 	case event.Has(fsnotify.Write):
 		log.Printf("[~] Modified: %s\n", event.Name)
 	case event.Has(fsnotify.Remove):
 		log.Printf("[-] Removed: %s\n", event.Name)
-		// bf.Rescan()
 	case event.Has(fsnotify.Rename):
 		log.Printf("[>] Renamed: %s\n", event.Name)
-		// bf.Rescan()
 	}
+	bf.armRescan(event)
 }
 func (bf *BroomFolder) deInit() {
 	if !bf.isInitialized() {
@@ -87,8 +125,11 @@ func (bf *BroomFolder) deInit() {
 	if bf.list != nil {
 		bf.list = nil
 	}
+	if bf.rescan != nil {
+		bf.rescan.stop()
+	}
 }
-func (bf *BroomFolder) fetchMetadata() {
+func (bf *BroomFolder) fetchMetadata(ctx context.Context) {
 	if bf.isInitialized() {
 		return
 	}
@@ -100,6 +141,9 @@ func (bf *BroomFolder) fetchMetadata() {
 	// Foreach style loop
 	i := 0
 	for e := bf.list.Front(); e != nil; e = e.Next() {
+		if ctx.Err() != nil {
+			break
+		}
 		file := e.Value.(File)
 		file.Metadata = nil
 		file.Metadata = map[string]any{}
@@ -150,12 +194,12 @@ func (bf *BroomFolder) watch() {
 	go func(bf *BroomFolder) {
 		for {
 			select {
-			case event, ok := <-bf.watcher.Events:
+			case event, ok := <-bf.watcher.Events():
 				if !ok {
 					return
 				}
 				bf.onFolderEvent(event)
-			case err, ok := <-bf.watcher.Errors:
+			case err, ok := <-bf.watcher.Errors():
 				if !ok {
 					return
 				}
@@ -165,37 +209,36 @@ func (bf *BroomFolder) watch() {
 	}(bf)
 
 }
-func (bf *BroomFolder) initialize() error {
+func (bf *BroomFolder) initialize(ctx context.Context) error {
 	if bf.isInitialized() {
 		return nil
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-	if err := bf.scan(); err != nil {
+	if err := bf.scan(ctx); err != nil {
 		return err
 	}
+	bf.rescan = &rescanState{}
 
-	watcher, err := fsnotify.NewWatcher()
+	watcher, err := bf.fs().Watch(bf.Location)
 	if err != nil {
-		return fmt.Errorf("failed to create watcher: %w", err)
+		return fmt.Errorf("failed to watch folder %q: %w", bf.Location, err)
 	}
 	bf.watcher = watcher
 	bf.watch()
 
-	if err := bf.watcher.Add(bf.Location); err != nil {
-		bf.watcher.Close()
-		return fmt.Errorf("failed to watch folder %q: %w", bf.Location, err)
-	}
-
-	bf.fetchMetadata()
-	if err := bf.check(); err != nil {
+	bf.fetchMetadata(ctx)
+	if err := bf.check(ctx); err != nil {
 		bf.deInit()
 		return err
 	}
 
 	return nil
 }
-func (bf *BroomFolder) scan() error {
-	files, err := collectFiles(bf.Location, bf.parent.exts, false)
+func (bf *BroomFolder) scan(ctx context.Context) error {
+	files, err := collectFiles(ctx, bf.fs(), bf.Location, bf.parent.exts, false, bf.loadIgnore())
 	if err != nil {
 		return err
 	}
@@ -215,15 +258,23 @@ func (bf *BroomFolder) scan() error {
 }
 
 // check folder if execed max size it will delete some files
-func (bf *BroomFolder) check() error {
+func (bf *BroomFolder) check(ctx context.Context) error {
 	if !bf.isInitialized() {
 		return nil
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	br := bf.parent
 	if bf.MaxSize != 0 && bf.MaxSize < bf.CurrentSize {
 		if br.RemovingStrategy != nil {
 			rms := br.RemovingStrategy(bf, bf.list, bf.CurrentSize-bf.MaxSize)
-			err := DeleteFiles(rms)
+			var err error
+			if br.Versioner != nil {
+				err = br.Versioner.Archive(bf, rms)
+			} else {
+				err = deleteFiles(ctx, bf.fs(), rms)
+			}
 			if br.onRemoveCb != nil {
 				br.onRemoveCb(bf, rms)
 			}
@@ -245,7 +296,9 @@ func (br *Broom) handleQueue(op broomOperation) {
 				err: ErrFolderExist,
 			}
 		} else {
-			br.folders[op.folder.Location] = &op.folder
+			f := op.folder
+			f.parent = br
+			br.folders[f.Location] = &f
 			ret = &broomOperationResponse{
 				err: nil,
 			}
@@ -259,7 +312,7 @@ func (br *Broom) handleQueue(op broomOperation) {
 		} else {
 			var err error
 			if !got.isInitialized() {
-				err = got.initialize()
+				err = got.initialize(op.ctx)
 			}
 			ret = &broomOperationResponse{
 				err:  err,
@@ -289,11 +342,29 @@ func (br *Broom) handleQueue(op broomOperation) {
 			}
 		} else {
 			folder.deInit()
-			err := folder.initialize()
+			err := folder.initialize(op.ctx)
 			ret = &broomOperationResponse{
 				err: err,
 			}
 		}
+	case OperationSetIgnores:
+		if folder, exist := br.folders[op.folder.Location]; !exist {
+			ret = &broomOperationResponse{
+				err: ErrFolderNotExist,
+			}
+		} else {
+			folder.SetIgnores(op.folder.ignoreLines)
+			ret = &broomOperationResponse{
+				err: nil,
+			}
+		}
+	case OperationRescan:
+		if folder, exist := br.folders[op.folder.Location]; exist {
+			folder.applyRescan(op.ctx, op.events)
+		}
+		ret = &broomOperationResponse{
+			err: nil,
+		}
 
 	default:
 		panic("not handled operation")
@@ -348,7 +419,11 @@ func (br *Broom) handle(wait time.Duration, ctx context.Context) bool {
 func (br *Broom) loop(ctx context.Context) {
 
 	for {
-		if br.handle(br.sweepTime, ctx) {
+		done := br.handle(br.sweepTime, ctx)
+		if err := br.checkGlobal(ctx); err != nil {
+			log.Printf("broom: global quota check failed: %v", err)
+		}
+		if done {
 			goto exit
 		}
 	}
@@ -1,5 +1,11 @@
 package broom
 
+import (
+	"context"
+
+	"github.com/fsnotify/fsnotify"
+)
+
 type Operation uint
 
 const (
@@ -8,6 +14,8 @@ const (
 	OperationGet
 	OperationPing
 	OperationRecheck
+	OperationRescan
+	OperationSetIgnores
 )
 
 type broomOperationResponse struct {
@@ -19,4 +27,8 @@ type broomOperation struct {
 	op     Operation
 	folder BroomFolder
 	sig    chan broomOperationResponse
+	ctx    context.Context
+	// events carries the coalesced fsnotify batch for OperationRescan; unused
+	// by every other operation.
+	events map[string]fsnotify.Event
 }
@@ -0,0 +1,255 @@
+package broom
+
+import (
+	"container/list"
+	"context"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Tiered pull intervals for the fsnotify-driven rescan pipeline, modeled on
+// Syncthing's rwfolder pull scheduler: a burst of events debounces down to
+// shortPullIntv, repeated bursts are coalesced up to a hard ceiling of
+// nextPullIntv, and a failed incremental rescan backs off to pauseIntv
+// before the folder is allowed to rescan again.
+const (
+	shortPullIntv = 1 * time.Second
+	nextPullIntv  = 10 * time.Second
+	pauseIntv     = 60 * time.Second
+)
+
+// rescanState is the debounce/timer state backing a folder's fsnotify-driven
+// rescan pipeline. It's held behind a pointer on BroomFolder (see the comment
+// there) so every copy of a folder shares the same live state.
+type rescanState struct {
+	mu         sync.Mutex
+	timer      *time.Timer
+	armedAt    time.Time
+	pauseUntil time.Time
+	pending    map[string]fsnotify.Event
+}
+
+// stop cancels any armed timer and drops pending events; called once a
+// folder is being torn down.
+func (rs *rescanState) stop() {
+	rs.mu.Lock()
+	if rs.timer != nil {
+		rs.timer.Stop()
+		rs.timer = nil
+	}
+	rs.pending = nil
+	rs.mu.Unlock()
+}
+
+// armRescan coalesces event into the folder's pending batch and (re)arms the
+// debounce timer. Repeated events reset the timer back to shortPullIntv, but
+// never push the first fire further out than nextPullIntv from the initial
+// event, so a folder under constant churn still rescans periodically.
+func (bf *BroomFolder) armRescan(event fsnotify.Event) {
+	rs := bf.rescan
+	if rs == nil {
+		return
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.pending == nil {
+		rs.pending = make(map[string]fsnotify.Event)
+	}
+	rs.pending[event.Name] = event
+
+	now := time.Now()
+	if rs.timer == nil {
+		wait := shortPullIntv
+		if now.Before(rs.pauseUntil) {
+			wait = rs.pauseUntil.Sub(now)
+		}
+		rs.armedAt = now
+		rs.timer = time.AfterFunc(wait, func() { bf.fireRescan(rs) })
+		return
+	}
+
+	ceiling := rs.armedAt.Add(nextPullIntv)
+	remaining := ceiling.Sub(now)
+	if remaining <= 0 {
+		return // already at the hard ceiling; let the pending timer fire
+	}
+	wait := shortPullIntv
+	if remaining < wait {
+		wait = remaining
+	}
+	rs.timer.Reset(wait)
+}
+
+// fireRescan runs on the timer's own goroutine once the debounce window
+// closes. It hands the coalesced batch off to the operation queue so the
+// rescan is applied on the same goroutine that owns folder state.
+func (bf *BroomFolder) fireRescan(rs *rescanState) {
+	rs.mu.Lock()
+	events := rs.pending
+	rs.pending = nil
+	rs.timer = nil
+	rs.mu.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+
+	op := broomOperation{
+		op:     OperationRescan,
+		folder: BroomFolder{Location: bf.Location},
+		sig:    make(chan broomOperationResponse, 1),
+		ctx:    context.Background(),
+		events: events,
+	}
+	select {
+	case bf.parent.operationQueue <- op:
+	case <-time.After(shortPullIntv):
+		// The queue is backed up or the broom has stopped; drop this batch
+		// rather than block the fsnotify watcher goroutine indefinitely. The
+		// next fsnotify event re-arms the debounce and tries again.
+	}
+}
+
+// applyRescan incrementally updates the folder's file list and CurrentSize
+// from a coalesced batch of fsnotify events. If any event can't be resolved
+// incrementally (e.g. a stat race), it falls back to a full scan and backs
+// off future rescans of this folder to pauseIntv.
+func (bf *BroomFolder) applyRescan(ctx context.Context, events map[string]fsnotify.Event) {
+	if !bf.isInitialized() {
+		return
+	}
+	for _, event := range events {
+		if err := bf.applyRescanEvent(event); err != nil {
+			log.Printf("broom: incremental rescan of %s failed (%v), falling back to full scan", event.Name, err)
+			if err := bf.scan(ctx); err != nil {
+				log.Printf("broom: full scan fallback for %s failed: %v", bf.Location, err)
+			}
+			bf.armPause()
+			break
+		}
+	}
+	if err := bf.check(ctx); err != nil {
+		log.Printf("broom: post-rescan check for %s failed: %v", bf.Location, err)
+	}
+}
+
+// armPause forces the next rescan to wait out pauseIntv instead of the usual
+// shortPullIntv, giving a folder that just needed a full scan time to settle.
+func (bf *BroomFolder) armPause() {
+	if bf.rescan == nil {
+		return
+	}
+	bf.rescan.mu.Lock()
+	bf.rescan.pauseUntil = time.Now().Add(pauseIntv)
+	bf.rescan.mu.Unlock()
+}
+
+// applyRescanEvent applies a single coalesced event to bf.list/CurrentSize.
+// It runs on the operation-queue loop goroutine (via applyRescan), so unlike
+// onFolderEvent it's safe to consult bf.loadIgnore() here.
+func (bf *BroomFolder) applyRescanEvent(event fsnotify.Event) error {
+	if rel, err := filepath.Rel(bf.Location, event.Name); err == nil {
+		// isDir comes from a best-effort Stat: a Create/Write/Rename event's
+		// path still exists, so this tells dirOnly patterns (e.g. "build/")
+		// apart from a same-named file. A Remove event's path is already
+		// gone, so isDir falls back to false and dirOnly patterns can't match
+		// it; insertCreated/removeTracked still reconcile the folder's
+		// contents correctly either way.
+		isDir := false
+		if info, err := bf.fs().Stat(event.Name); err == nil {
+			isDir = info.IsDir
+		}
+		if ignored, deletable := bf.loadIgnore().Match(rel, isDir); ignored && !deletable {
+			return nil
+		}
+	}
+	switch {
+	case event.Has(fsnotify.Create):
+		return bf.insertCreated(event.Name)
+	case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+		// fsnotify's Rename event only reports the path being renamed away
+		// from; the new name arrives as its own Create event, so treating
+		// it like a Remove here and letting the Create insert the new node
+		// keeps the list correct without needing cross-event correlation.
+		bf.removeTracked(event.Name)
+		return nil
+	}
+	return nil
+}
+
+// insertCreated stats path and, if it should be tracked, inserts a File node
+// into bf.list in create-time order and adds its size to CurrentSize. A stat
+// error (the file already vanished again) is returned so the caller falls
+// back to a full scan instead of leaving the list inconsistent.
+func (bf *BroomFolder) insertCreated(path string) error {
+	info, err := bf.fs().Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir {
+		return nil
+	}
+	if !hasAllowedExtension(info.Name, bf.parent.exts) {
+		return nil
+	}
+
+	rel, err := filepath.Rel(bf.Location, path)
+	if err != nil {
+		rel = info.Name
+	}
+	ignored, deletable := bf.loadIgnore().Match(rel, false)
+	if ignored && !deletable {
+		return nil
+	}
+
+	f := File{
+		Path:      path,
+		Name:      info.Name,
+		IsDir:     false,
+		Size:      info.Size,
+		CreateAt:  info.CreateAt,
+		UpdateAt:  info.UpdateAt,
+		Metadata:  map[string]any{},
+		Ignored:   ignored,
+		Deletable: deletable,
+	}
+	bf.insertSorted(f)
+	bf.CurrentSize += f.Size
+	return nil
+}
+
+// insertSorted inserts f into bf.list keeping create-time ascending order,
+// walking back from the tail since new files are usually the newest.
+func (bf *BroomFolder) insertSorted(f File) {
+	if bf.list == nil {
+		bf.list = list.New()
+	}
+	for e := bf.list.Back(); e != nil; e = e.Prev() {
+		if existing, ok := e.Value.(File); ok && !existing.CreateAt.After(f.CreateAt) {
+			bf.list.InsertAfter(f, e)
+			return
+		}
+	}
+	bf.list.PushFront(f)
+}
+
+// removeTracked finds the node for path and unlinks it from bf.list,
+// subtracting its size from CurrentSize. A path that isn't tracked (already
+// removed, or never matched) is a silent no-op.
+func (bf *BroomFolder) removeTracked(path string) {
+	if bf.list == nil {
+		return
+	}
+	for e := bf.list.Front(); e != nil; e = e.Next() {
+		if f, ok := e.Value.(File); ok && f.Path == path {
+			bf.CurrentSize -= f.Size
+			bf.list.Remove(e)
+			return
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package broom
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestApplyRescanEventIncrementallyUpdatesListAndSize(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "broom_rescan_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	bf := &BroomFolder{Location: tmpDir, parent: &Broom{}}
+	if err := bf.scan(context.Background()); err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	if bf.CurrentSize != 0 {
+		t.Fatalf("expected empty folder to start at size 0, got %v", bf.CurrentSize)
+	}
+
+	path := filepath.Join(tmpDir, "new.bin")
+	if err := createFile(path, 10*Byte); err != nil {
+		t.Fatal(err)
+	}
+	if err := bf.applyRescanEvent(fsnotify.Event{Name: path, Op: fsnotify.Create}); err != nil {
+		t.Fatalf("applyRescanEvent(Create) failed: %v", err)
+	}
+	if bf.CurrentSize != 10*Byte {
+		t.Fatalf("expected CurrentSize 10 after create, got %v", bf.CurrentSize)
+	}
+	if bf.list.Len() != 1 {
+		t.Fatalf("expected 1 tracked file after create, got %d", bf.list.Len())
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := bf.applyRescanEvent(fsnotify.Event{Name: path, Op: fsnotify.Remove}); err != nil {
+		t.Fatalf("applyRescanEvent(Remove) failed: %v", err)
+	}
+	if bf.CurrentSize != 0 {
+		t.Fatalf("expected CurrentSize 0 after remove, got %v", bf.CurrentSize)
+	}
+	if bf.list.Len() != 0 {
+		t.Fatalf("expected 0 tracked files after remove, got %d", bf.list.Len())
+	}
+}
+
+// TestApplyRescanEventMatchesDirOnlyPatternAgainstCreatedDirectory exercises
+// the case a hardcoded isDir=false would miss: a dirOnly ignore pattern
+// (e.g. "build/") must match the Create event fired for the ignored
+// directory itself, which applyRescanEvent can only know by stat'ing
+// event.Name. This check used to run in onFolderEvent, on the raw fsnotify
+// watcher goroutine, where it raced with loadIgnore's cache writes from the
+// operation-queue loop goroutine; it now runs here instead, alongside the
+// rest of the queue-owned rescan application.
+func TestApplyRescanEventMatchesDirOnlyPatternAgainstCreatedDirectory(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "broom_rescan_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	buildDir := filepath.Join(tmpDir, "build")
+	if err := os.Mkdir(buildDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	bf := &BroomFolder{
+		Location:    tmpDir,
+		ignoreLines: []string{"build/"},
+		parent:      &Broom{},
+	}
+
+	if err := bf.applyRescanEvent(fsnotify.Event{Name: buildDir, Op: fsnotify.Create}); err != nil {
+		t.Fatalf("applyRescanEvent failed: %v", err)
+	}
+
+	if bf.list != nil && bf.list.Len() != 0 {
+		t.Fatalf("expected the ignored build/ directory to be skipped, got %d tracked entries", bf.list.Len())
+	}
+	if bf.CurrentSize != 0 {
+		t.Fatalf("expected CurrentSize to stay 0 for the ignored build/ directory, got %v", bf.CurrentSize)
+	}
+}
+
+func TestArmRescanCoalescesBurstsIntoSingleFire(t *testing.T) {
+	bf := &BroomFolder{
+		Location: "/tmp/does-not-matter",
+		parent:   &Broom{operationQueue: make(chan broomOperation, 10)},
+		rescan:   &rescanState{},
+	}
+
+	for i := 0; i < 5; i++ {
+		bf.armRescan(fsnotify.Event{Name: "a", Op: fsnotify.Create})
+		time.Sleep(shortPullIntv / 10)
+	}
+
+	select {
+	case op := <-bf.parent.operationQueue:
+		if op.op != OperationRescan {
+			t.Fatalf("expected OperationRescan, got %v", op.op)
+		}
+		if len(op.events) != 1 {
+			t.Fatalf("expected the burst to coalesce into 1 pending event, got %d", len(op.events))
+		}
+	case <-time.After(shortPullIntv * 3):
+		t.Fatal("timed out waiting for debounced rescan to fire")
+	}
+}
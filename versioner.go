@@ -0,0 +1,295 @@
+package broom
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// versionTimeFormat is the timestamp suffix appended to archived files by the
+// versioners in this file, e.g. "video.mp4.20060102-150405".
+const versionTimeFormat = "20060102-150405"
+
+// Versioner decides what happens to files selected for removal instead of
+// deleting them outright. check() hands the victims to the folder's
+// Versioner (if any) before falling back to DeleteFiles.
+type Versioner interface {
+	// Archive takes ownership of files, moving, copying, or pruning them as
+	// the implementation sees fit. Files that Archive does not otherwise
+	// dispose of are expected to be gone from folder.Location on return.
+	Archive(folder *BroomFolder, files []File) error
+}
+
+// archiveDest builds the destination path for file inside versionsDir,
+// preserving its path relative to folder.Location and appending a
+// versionTimeFormat timestamp so multiple versions of the same file can
+// coexist.
+func archiveDest(folder *BroomFolder, versionsDir string, file File, at time.Time) string {
+	rel, err := filepath.Rel(folder.Location, file.Path)
+	if err != nil {
+		rel = file.Name
+	}
+	return filepath.Join(versionsDir, rel+"."+at.Format(versionTimeFormat))
+}
+
+// moveToArchive renames file into versionsDir through fs, creating any
+// intermediate directories needed to mirror its original layout.
+func moveToArchive(fs Filesystem, folder *BroomFolder, versionsDir string, file File, at time.Time) (string, error) {
+	dest := archiveDest(folder, versionsDir, file, at)
+	if err := fs.MkdirAll(filepath.Dir(dest)); err != nil {
+		return "", fmt.Errorf("failed to create archive dir: %w", err)
+	}
+	if err := fs.Rename(file.Path, dest); err != nil {
+		return "", fmt.Errorf("failed to archive %s: %w", file.Path, err)
+	}
+	return dest, nil
+}
+
+// walkVersions returns the full path of every non-directory entry under dir,
+// recursing through fs the way filepath.Walk would. A missing dir (e.g. no
+// version has been archived yet) is treated as empty rather than an error.
+func walkVersions(fs Filesystem, dir string) ([]string, error) {
+	entries, err := fs.ReadDir(dir)
+	if err != nil {
+		return nil, nil
+	}
+	var out []string
+	for _, e := range entries {
+		full := filepath.Join(dir, e.Name)
+		if e.IsDir {
+			sub, err := walkVersions(fs, full)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, sub...)
+			continue
+		}
+		out = append(out, full)
+	}
+	return out, nil
+}
+
+// splitVersionSuffix parses a path under root produced by moveToArchive,
+// returning the original relative path and the timestamp it was archived at.
+func splitVersionSuffix(root, path string) (rel string, at time.Time, ok bool) {
+	full, err := filepath.Rel(root, path)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	full = filepath.ToSlash(full)
+	idx := strings.LastIndex(full, ".")
+	if idx == -1 {
+		return "", time.Time{}, false
+	}
+	at, err = time.Parse(versionTimeFormat, full[idx+1:])
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return full[:idx], at, true
+}
+
+// TrashcanVersioner moves victims into a per-folder ".broom-trash" directory
+// instead of deleting them, and prunes entries older than CleanoutDays on
+// every Archive call. A CleanoutDays of 0 disables pruning.
+type TrashcanVersioner struct {
+	CleanoutDays int
+}
+
+// trashDirName is the per-folder directory TrashcanVersioner archives into.
+const trashDirName = ".broom-trash"
+
+func (v *TrashcanVersioner) Archive(folder *BroomFolder, files []File) error {
+	fs := folder.fs()
+	trashDir := filepath.Join(folder.Location, trashDirName)
+	now := time.Now()
+	for _, file := range files {
+		if file.IsDir {
+			continue
+		}
+		if _, err := moveToArchive(fs, folder, trashDir, file, now); err != nil {
+			return err
+		}
+	}
+	return v.cleanout(fs, trashDir)
+}
+
+// cleanout removes trashed files older than CleanoutDays.
+func (v *TrashcanVersioner) cleanout(fs Filesystem, trashDir string) error {
+	if v.CleanoutDays <= 0 {
+		return nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -v.CleanoutDays)
+	paths, err := walkVersions(fs, trashDir)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if _, at, ok := splitVersionSuffix(trashDir, path); ok && at.Before(cutoff) {
+			if err := fs.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SimpleVersioner archives victims into a per-folder ".broom-versions"
+// directory and keeps only the last Keep timestamped copies of each
+// original path, removing older ones as new versions arrive.
+type SimpleVersioner struct {
+	Keep int
+}
+
+// versionsDirName is the per-folder directory SimpleVersioner and
+// StaggeredVersioner archive into.
+const versionsDirName = ".broom-versions"
+
+func (v *SimpleVersioner) Archive(folder *BroomFolder, files []File) error {
+	fs := folder.fs()
+	versionsDir := filepath.Join(folder.Location, versionsDirName)
+	now := time.Now()
+	for _, file := range files {
+		if file.IsDir {
+			continue
+		}
+		dest, err := moveToArchive(fs, folder, versionsDir, file, now)
+		if err != nil {
+			return err
+		}
+		rel, _, _ := splitVersionSuffix(versionsDir, dest)
+		if err := v.thin(fs, versionsDir, rel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// thin removes the oldest archived copies of rel until at most Keep remain.
+func (v *SimpleVersioner) thin(fs Filesystem, versionsDir, rel string) error {
+	if v.Keep <= 0 {
+		return nil
+	}
+	dir := filepath.Join(versionsDir, filepath.Dir(rel))
+	prefix := filepath.Base(rel) + "."
+	entries, err := fs.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var matches []string
+	for _, e := range entries {
+		if !e.IsDir && strings.HasPrefix(e.Name, prefix) {
+			matches = append(matches, filepath.Join(dir, e.Name))
+		}
+	}
+	sort.Strings(matches) // timestamp suffix sorts chronologically
+	for len(matches) > v.Keep {
+		if err := fs.Remove(matches[0]); err != nil {
+			return fmt.Errorf("failed to prune old version %s: %w", matches[0], err)
+		}
+		matches = matches[1:]
+	}
+	return nil
+}
+
+// Bucket defines a retention slot covering ages in [Low, High). A staggered
+// schedule keeps at most one archived version per bucket, e.g. a day of
+// hourly buckets followed by a week of daily buckets.
+type Bucket struct {
+	Low  time.Duration
+	High time.Duration
+}
+
+// StaggeredVersioner archives victims into a per-folder ".broom-versions"
+// directory and thins them using an interval-bucket schedule: each Bucket
+// retains at most one version whose age falls within it, and any version
+// older than MaxAge (when positive) is evicted outright.
+type StaggeredVersioner struct {
+	MaxAge    time.Duration
+	Intervals []Bucket
+
+	// Now returns the current time and defaults to time.Now; tests override
+	// it to drive thinning against a controlled clock.
+	Now func() time.Time
+}
+
+func (v *StaggeredVersioner) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+	return time.Now()
+}
+
+func (v *StaggeredVersioner) Archive(folder *BroomFolder, files []File) error {
+	fs := folder.fs()
+	versionsDir := filepath.Join(folder.Location, versionsDirName)
+	now := v.now()
+	for _, file := range files {
+		if file.IsDir {
+			continue
+		}
+		if _, err := moveToArchive(fs, folder, versionsDir, file, now); err != nil {
+			return err
+		}
+	}
+	return v.thin(fs, versionsDir)
+}
+
+type versionEntry struct {
+	path string
+	at   time.Time
+}
+
+// thin applies the staggered retention schedule across every version
+// recorded under versionsDir, grouped by original relative path.
+func (v *StaggeredVersioner) thin(fs Filesystem, versionsDir string) error {
+	paths, err := walkVersions(fs, versionsDir)
+	if err != nil {
+		return err
+	}
+	groups := make(map[string][]versionEntry)
+	for _, path := range paths {
+		rel, at, ok := splitVersionSuffix(versionsDir, path)
+		if !ok {
+			continue
+		}
+		groups[rel] = append(groups[rel], versionEntry{path: path, at: at})
+	}
+
+	now := v.now()
+	for _, entries := range groups {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].at.After(entries[j].at) })
+
+		kept := make([]bool, len(entries))
+		for _, b := range v.Intervals {
+			best := -1
+			for i, e := range entries {
+				if kept[i] {
+					continue
+				}
+				age := now.Sub(e.at)
+				if age >= b.Low && age < b.High {
+					best = i
+					break // entries is newest-first, so the first match is the newest in-bucket
+				}
+			}
+			if best != -1 {
+				kept[best] = true
+			}
+		}
+
+		for i, e := range entries {
+			evict := !kept[i]
+			if v.MaxAge > 0 && now.Sub(e.at) >= v.MaxAge {
+				evict = true
+			}
+			if evict {
+				if err := fs.Remove(e.path); err != nil {
+					return fmt.Errorf("failed to prune version %s: %w", e.path, err)
+				}
+			}
+		}
+	}
+	return nil
+}
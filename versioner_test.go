@@ -0,0 +1,191 @@
+package broom
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTrashcanVersionerArchive(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "broom_versioner_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	victim := filepath.Join(tmpDir, "old.log")
+	if err := createFile(victim, 10*Byte); err != nil {
+		t.Fatal(err)
+	}
+
+	folder := &BroomFolder{Location: tmpDir}
+	v := &TrashcanVersioner{CleanoutDays: 0}
+
+	if err := v.Archive(folder, []File{{Path: victim, Name: "old.log"}}); err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+
+	if _, err := os.Stat(victim); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be moved out of the folder", victim)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(tmpDir, trashDirName, "old.log.*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 trashed copy of old.log, got %d", len(matches))
+	}
+}
+
+func TestTrashcanVersionerCleanout(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "broom_versioner_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	trashDir := filepath.Join(tmpDir, trashDirName)
+	if err := os.MkdirAll(trashDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := filepath.Join(trashDir, "old.log."+time.Now().Add(-48*time.Hour).Format(versionTimeFormat))
+	fresh := filepath.Join(trashDir, "old.log."+time.Now().Format(versionTimeFormat))
+	if err := createFile(stale, 1*Byte); err != nil {
+		t.Fatal(err)
+	}
+	if err := createFile(fresh, 1*Byte); err != nil {
+		t.Fatal(err)
+	}
+
+	v := &TrashcanVersioner{CleanoutDays: 1}
+	if err := v.cleanout(OSFilesystem{}, trashDir); err != nil {
+		t.Fatalf("cleanout failed: %v", err)
+	}
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("expected stale trash entry to be pruned")
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("expected fresh trash entry to survive cleanout: %v", err)
+	}
+}
+
+func TestSimpleVersionerKeepsOnlyLastN(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "broom_versioner_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	folder := &BroomFolder{Location: tmpDir}
+	v := &SimpleVersioner{Keep: 2}
+
+	for i := 0; i < 3; i++ {
+		victim := filepath.Join(tmpDir, "data.bin")
+		if err := createFile(victim, 1*Byte); err != nil {
+			t.Fatal(err)
+		}
+		if err := v.Archive(folder, []File{{Path: victim, Name: "data.bin"}}); err != nil {
+			t.Fatalf("Archive failed: %v", err)
+		}
+		time.Sleep(time.Second) // ensure distinct timestamp suffixes
+	}
+
+	matches, err := filepath.Glob(filepath.Join(tmpDir, versionsDirName, "data.bin.*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 retained versions, got %d", len(matches))
+	}
+}
+
+func TestStaggeredVersionerThinning(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "broom_versioner_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	versionsDir := filepath.Join(tmpDir, versionsDirName)
+	if err := os.MkdirAll(versionsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, time.July, 28, 12, 0, 0, 0, time.UTC)
+	ages := []time.Duration{
+		1 * time.Hour,       // falls in the [0, 24h) bucket
+		3 * 24 * time.Hour,  // falls in the [24h, 7d) bucket
+		10 * 24 * time.Hour, // falls in the [7d, 30d) bucket
+		40 * 24 * time.Hour, // older than MaxAge, must be evicted outright
+	}
+	for _, age := range ages {
+		at := now.Add(-age)
+		path := filepath.Join(versionsDir, "report.csv."+at.Format(versionTimeFormat))
+		if err := createFile(path, 1*Byte); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	v := &StaggeredVersioner{
+		MaxAge: 30 * 24 * time.Hour,
+		Intervals: []Bucket{
+			{Low: 0, High: 24 * time.Hour},                       // last day: keep newest
+			{Low: 24 * time.Hour, High: 7 * 24 * time.Hour},      // last week
+			{Low: 7 * 24 * time.Hour, High: 30 * 24 * time.Hour}, // last month
+		},
+		Now: func() time.Time { return now },
+	}
+
+	if err := v.thin(OSFilesystem{}, versionsDir); err != nil {
+		t.Fatalf("thin failed: %v", err)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(versionsDir, "report.csv.*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 3 {
+		t.Fatalf("expected 3 surviving versions (one per bucket), got %d: %v", len(matches), matches)
+	}
+}
+
+// TestSimpleVersionerWithFakeFilesystem exercises check() with both a
+// Versioner and a FakeFilesystem configured, the combination
+// WithVersioner and WithFilesystem are meant to support: the archive must
+// move the victim through the fake tree instead of touching real disk.
+func TestSimpleVersionerWithFakeFilesystem(t *testing.T) {
+	fsys := NewFakeFilesystem()
+	fsys.AddDir("/virtual/folder")
+	fsys.AddFile("/virtual/folder/old.bin", 600*KByte, time.Unix(1000, 0))
+	fsys.AddFile("/virtual/folder/new.bin", 300*KByte, time.Unix(2000, 0))
+
+	br := &Broom{
+		folders:          make(map[string]*BroomFolder),
+		RemovingStrategy: DEFAULT_REMOVING_STRATEGY,
+		Filesystem:       fsys,
+		Versioner:        &SimpleVersioner{Keep: 1},
+	}
+	bf := &BroomFolder{Location: "/virtual/folder", MaxSize: 400 * KByte, parent: br}
+
+	if err := bf.initialize(context.Background()); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	defer bf.deInit()
+
+	if _, err := fsys.Stat("/virtual/folder/old.bin"); err == nil {
+		t.Errorf("expected the oldest file to have been archived out of the folder")
+	}
+
+	entries, err := fsys.ReadDir(filepath.Join("/virtual/folder", versionsDirName))
+	if err != nil {
+		t.Fatalf("expected archived versions dir to exist in the fake filesystem: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 archived copy of old.bin, got %d: %v", len(entries), entries)
+	}
+}